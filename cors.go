@@ -0,0 +1,122 @@
+package gorouter
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// CorsOptions configures the behavior of the Cors middleware.
+type CorsOptions struct {
+	// AllowedOrigins is the list of origins a cross-domain request is
+	// allowed from. A "*" entry allows any origin.
+	AllowedOrigins []string
+	// AllowedMethods is the list of http methods advertised as allowed in
+	// response to a preflight request.
+	AllowedMethods []string
+	// AllowedHeaders is the list of headers the client is allowed to send,
+	// advertised in response to a preflight request.
+	AllowedHeaders []string
+	// AllowCredentials indicates whether the response can be exposed when
+	// the request includes credentials like cookies or authorization
+	// headers.
+	AllowCredentials bool
+	// MaxAge indicates, in seconds, how long the results of a preflight
+	// request can be cached by the browser. Zero omits the header.
+	MaxAge int
+}
+
+// Cors records opts as the router's CORS configuration and returns a
+// MiddlewareType that sets the Access-Control-* response headers on simple
+// (non-preflight) requests before calling through. Preflight requests are
+// not handled here: they are answered by ServeHTTP itself, resolved
+// against whatever routes are registered at request time rather than a
+// snapshot taken when Cors is called, so Cors works no matter which order
+// it is called in relative to Use or the routes it protects.
+func (router *Router) Cors(opts CorsOptions) MiddlewareType {
+	router.cors = &opts
+
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			origin := r.Header.Get("Origin")
+
+			if origin == "" || !opts.originAllowed(origin) {
+				next(w, r)
+				return
+			}
+
+			opts.setCommonHeaders(w, origin)
+			next(w, r)
+		}
+	}
+}
+
+// handlePreflight answers r directly if router.cors is configured, r is a
+// CORS preflight request from an allowed origin, requestUrl has at least
+// one route registered under some other method, and requestUrl has no
+// explicitly registered OPTIONS handler of its own (which always takes
+// precedence over the automatic answer). It reports whether it answered r.
+func (router *Router) handlePreflight(w http.ResponseWriter, r *http.Request, requestUrl string) bool {
+	if router.cors == nil || r.Method != http.MethodOptions {
+		return false
+	}
+
+	if r.Header.Get("Access-Control-Request-Method") == "" {
+		return false
+	}
+
+	origin := r.Header.Get("Origin")
+	if origin == "" || !router.cors.originAllowed(origin) {
+		return false
+	}
+
+	if tree, ok := router.trees[http.MethodOptions]; ok && tree.Match(requestUrl) {
+		return false
+	}
+
+	if len(router.allowedMethods(requestUrl)) == 0 {
+		return false
+	}
+
+	router.cors.setCommonHeaders(w, origin)
+	router.cors.setPreflightHeaders(w)
+	w.WriteHeader(http.StatusNoContent)
+	return true
+}
+
+// originAllowed reports whether origin is permitted by opts.
+func (opts CorsOptions) originAllowed(origin string) bool {
+	for _, allowed := range opts.AllowedOrigins {
+		if allowed == "*" || strings.EqualFold(allowed, origin) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// setCommonHeaders sets the headers that apply to both simple and
+// preflighted CORS responses.
+func (opts CorsOptions) setCommonHeaders(w http.ResponseWriter, origin string) {
+	w.Header().Set("Access-Control-Allow-Origin", origin)
+	w.Header().Add("Vary", "Origin")
+
+	if opts.AllowCredentials {
+		w.Header().Set("Access-Control-Allow-Credentials", "true")
+	}
+}
+
+// setPreflightHeaders sets the headers specific to a preflight response.
+func (opts CorsOptions) setPreflightHeaders(w http.ResponseWriter) {
+	if len(opts.AllowedMethods) > 0 {
+		w.Header().Set("Access-Control-Allow-Methods", strings.Join(opts.AllowedMethods, ", "))
+	}
+
+	if len(opts.AllowedHeaders) > 0 {
+		w.Header().Set("Access-Control-Allow-Headers", strings.Join(opts.AllowedHeaders, ", "))
+	}
+
+	if opts.MaxAge > 0 {
+		w.Header().Set("Access-Control-Max-Age", strconv.Itoa(opts.MaxAge))
+	}
+}
@@ -0,0 +1,131 @@
+package gorouter
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func corsOpts() CorsOptions {
+	return CorsOptions{
+		AllowedOrigins: []string{"https://example.com"},
+		AllowedMethods: []string{http.MethodGet, http.MethodPost},
+		AllowedHeaders: []string{"Content-Type"},
+		MaxAge:         600,
+	}
+}
+
+func preflightRequest(method, path, origin string) *http.Request {
+	r := httptest.NewRequest(http.MethodOptions, path, nil)
+	r.Header.Set("Origin", origin)
+	r.Header.Set("Access-Control-Request-Method", method)
+	return r
+}
+
+// TestCorsPreflightForRouteRegisteredBeforeCors covers a route registered
+// before Use(cors), whose middleware chain therefore lacks cors. Preflight
+// answering does not go through the tree's middleware chain at all, so it
+// must succeed regardless of when the route was registered relative to
+// Cors.
+func TestCorsPreflightForRouteRegisteredBeforeCors(t *testing.T) {
+	router := New()
+	router.GET("/users", noopHandler)
+	router.Use(router.Cors(corsOpts()))
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, preflightRequest(http.MethodGet, "/users", "https://example.com"))
+
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("preflight status = %d, want %d", rec.Code, http.StatusNoContent)
+	}
+
+	if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "https://example.com" {
+		t.Fatalf("preflight Access-Control-Allow-Origin = %q, want %q", got, "https://example.com")
+	}
+
+	if got := rec.Header().Get("Access-Control-Allow-Methods"); got == "" {
+		t.Fatalf("preflight Access-Control-Allow-Methods was not set")
+	}
+}
+
+// TestCorsRoutesRegisteredAfterUse covers a route registered after Cors
+// was called, which a preflight must still find since ServeHTTP resolves
+// preflights against the trees at request time. It also exercises the
+// ordinary case where both simple requests and preflights work end to end.
+func TestCorsRoutesRegisteredAfterUse(t *testing.T) {
+	router := New()
+
+	cors := router.Cors(corsOpts())
+	router.Use(cors)
+	router.GET("/users", noopHandler)
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/users", nil)
+	req.Header.Set("Origin", "https://example.com")
+	router.ServeHTTP(rec, req)
+
+	if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "https://example.com" {
+		t.Fatalf("Access-Control-Allow-Origin = %q, want %q", got, "https://example.com")
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, preflightRequest(http.MethodGet, "/users", "https://example.com"))
+
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("preflight status = %d, want %d", rec.Code, http.StatusNoContent)
+	}
+
+	if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "https://example.com" {
+		t.Fatalf("preflight Access-Control-Allow-Origin = %q, want %q", got, "https://example.com")
+	}
+}
+
+func TestCorsPreflightDisallowedOrigin(t *testing.T) {
+	router := New()
+	router.GET("/users", noopHandler)
+	router.Use(router.Cors(corsOpts()))
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, preflightRequest(http.MethodGet, "/users", "https://evil.example"))
+
+	if rec.Code == http.StatusNoContent {
+		t.Fatalf("preflight from a disallowed origin must not be answered 204")
+	}
+}
+
+func TestCorsPreflightUnknownPath(t *testing.T) {
+	router := New()
+	router.GET("/users", noopHandler)
+	router.Use(router.Cors(corsOpts()))
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, preflightRequest(http.MethodGet, "/missing", "https://example.com"))
+
+	if rec.Code == http.StatusNoContent {
+		t.Fatalf("preflight for an unregistered path must not be answered 204")
+	}
+}
+
+func TestCorsExplicitOptionsHandlerWins(t *testing.T) {
+	router := New()
+	router.GET("/users", noopHandler)
+
+	var customCalled bool
+	router.OPTIONS("/users", func(w http.ResponseWriter, r *http.Request) {
+		customCalled = true
+		w.WriteHeader(http.StatusTeapot)
+	})
+
+	router.Use(router.Cors(corsOpts()))
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, preflightRequest(http.MethodGet, "/users", "https://example.com"))
+
+	if !customCalled {
+		t.Fatalf("explicitly registered OPTIONS handler was not invoked")
+	}
+
+	if rec.Code != http.StatusTeapot {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusTeapot)
+	}
+}
@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/dufuqiang/gorouter"
+)
+
+// LogFunc receives one line of structured access log data per request.
+type LogFunc func(method, path string, status, bytes int, duration time.Duration)
+
+// Logger returns a MiddlewareType that wraps the ResponseWriter to capture
+// the status code and bytes written, then calls logFn with the request
+// method, path, status, byte count and latency.
+func Logger(logFn LogFunc) gorouter.MiddlewareType {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			lw := &loggingResponseWriter{ResponseWriter: w, status: http.StatusOK}
+
+			next(lw, r)
+
+			logFn(r.Method, r.URL.Path, lw.status, lw.bytes, time.Since(start))
+		}
+	}
+}
+
+// loggingResponseWriter wraps an http.ResponseWriter to capture the status
+// code and byte count written through it.
+type loggingResponseWriter struct {
+	http.ResponseWriter
+	status int
+	bytes  int
+}
+
+func (w *loggingResponseWriter) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *loggingResponseWriter) Write(b []byte) (int, error) {
+	n, err := w.ResponseWriter.Write(b)
+	w.bytes += n
+	return n, err
+}
@@ -0,0 +1,151 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRecoverer(t *testing.T) {
+	var recovered interface{}
+
+	h := Recoverer(func(w http.ResponseWriter, r *http.Request, err interface{}) {
+		recovered = err
+		w.WriteHeader(http.StatusTeapot)
+	})(func(w http.ResponseWriter, r *http.Request) {
+		panic(errors.New("boom"))
+	})
+
+	rec := httptest.NewRecorder()
+	h(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if rec.Code != http.StatusTeapot {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusTeapot)
+	}
+
+	if recovered == nil {
+		t.Fatalf("onPanic was not called")
+	}
+}
+
+func TestRequestID(t *testing.T) {
+	var seen string
+
+	h := RequestID()(func(w http.ResponseWriter, r *http.Request) {
+		seen = GetRequestID(r)
+	})
+
+	rec := httptest.NewRecorder()
+	h(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	header := rec.Header().Get(RequestIDHeader)
+	if header == "" {
+		t.Fatalf("%s header was not set", RequestIDHeader)
+	}
+
+	if seen != header {
+		t.Fatalf("GetRequestID() = %q, want %q", seen, header)
+	}
+}
+
+func TestLogger(t *testing.T) {
+	var gotStatus, gotBytes int
+
+	h := Logger(func(method, path string, status, bytes int, d time.Duration) {
+		gotStatus = status
+		gotBytes = bytes
+	})(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte("hello"))
+	})
+
+	rec := httptest.NewRecorder()
+	h(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if gotStatus != http.StatusCreated {
+		t.Fatalf("logged status = %d, want %d", gotStatus, http.StatusCreated)
+	}
+
+	if gotBytes != len("hello") {
+		t.Fatalf("logged bytes = %d, want %d", gotBytes, len("hello"))
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	h := Timeout(10 * time.Millisecond)(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	})
+
+	rec := httptest.NewRecorder()
+	start := time.Now()
+	h(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if time.Since(start) > time.Second {
+		t.Fatalf("handler did not observe context cancellation in time")
+	}
+
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusServiceUnavailable)
+	}
+}
+
+func TestTimeoutHandlerFinishesInTime(t *testing.T) {
+	h := Timeout(time.Second)(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte("done"))
+	})
+
+	rec := httptest.NewRecorder()
+	h(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusCreated)
+	}
+
+	if got := rec.Body.String(); got != "done" {
+		t.Fatalf("body = %q, want %q", got, "done")
+	}
+}
+
+func TestThrottle(t *testing.T) {
+	block := make(chan struct{})
+	release := make(chan struct{})
+
+	mw := Throttle(1)
+
+	h := mw(func(w http.ResponseWriter, r *http.Request) {
+		close(block)
+		<-release
+	})
+
+	go h(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	<-block
+
+	rec := httptest.NewRecorder()
+	h(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusServiceUnavailable)
+	}
+
+	close(release)
+}
+
+func TestRealIP(t *testing.T) {
+	var gotAddr string
+
+	h := RealIP()(func(w http.ResponseWriter, r *http.Request) {
+		gotAddr = r.RemoteAddr
+	})
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("X-Forwarded-For", "203.0.113.5, 10.0.0.1")
+
+	h(httptest.NewRecorder(), r)
+
+	if gotAddr != "203.0.113.5" {
+		t.Fatalf("RemoteAddr = %q, want %q", gotAddr, "203.0.113.5")
+	}
+}
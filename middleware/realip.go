@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/dufuqiang/gorouter"
+)
+
+// RealIP returns a MiddlewareType that overwrites r.RemoteAddr with the
+// client address reported by the X-Forwarded-For or X-Real-IP headers,
+// preferring the first entry of X-Forwarded-For. It should only be used
+// behind a trusted proxy that sets these headers itself, since they are
+// otherwise trivially spoofable by the client.
+func RealIP() gorouter.MiddlewareType {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			if fwd := r.Header.Get("X-Forwarded-For"); fwd != "" {
+				if ip := strings.TrimSpace(strings.Split(fwd, ",")[0]); ip != "" {
+					r.RemoteAddr = ip
+				}
+			} else if ip := r.Header.Get("X-Real-IP"); ip != "" {
+				r.RemoteAddr = ip
+			}
+
+			next(w, r)
+		}
+	}
+}
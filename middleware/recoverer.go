@@ -0,0 +1,36 @@
+// Package middleware provides a small set of production-ready
+// gorouter.MiddlewareType implementations: panic recovery, request IDs,
+// structured logging, timeouts, throttling and real client IP resolution.
+package middleware
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/dufuqiang/gorouter"
+)
+
+// Recoverer returns a MiddlewareType that recovers from a panic in the
+// handler chain. If onPanic is non-nil it is called with the recovered
+// value and writes the response itself; Recoverer then writes nothing. If
+// onPanic is nil, Recoverer logs the value and writes a 500 response. It
+// replaces the need to set Router.PanicHandler.
+func Recoverer(onPanic func(w http.ResponseWriter, r *http.Request, err interface{})) gorouter.MiddlewareType {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			defer func() {
+				if err := recover(); err != nil {
+					if onPanic != nil {
+						onPanic(w, r, err)
+						return
+					}
+
+					log.Printf("gorouter: recovered panic: %v", err)
+					http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+				}
+			}()
+
+			next(w, r)
+		}
+	}
+}
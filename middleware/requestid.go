@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"net/http"
+
+	"github.com/dufuqiang/gorouter"
+)
+
+// requestIDKeyType is a private struct used for storing the request ID in
+// the request context.
+type requestIDKeyType struct{}
+
+var requestIDKey = requestIDKeyType{}
+
+// RequestIDHeader is the response header RequestID stores the generated ID
+// under.
+const RequestIDHeader = "X-Request-ID"
+
+// RequestID returns a MiddlewareType that assigns each request a UUIDv4,
+// storing it on the request context and on the X-Request-ID response
+// header.
+func RequestID() gorouter.MiddlewareType {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			id := newRequestID()
+
+			w.Header().Set(RequestIDHeader, id)
+			ctx := context.WithValue(r.Context(), requestIDKey, id)
+			next(w, r.WithContext(ctx))
+		}
+	}
+}
+
+// GetRequestID returns the request ID assigned by RequestID, or "" if none
+// was assigned.
+func GetRequestID(r *http.Request) string {
+	id, _ := r.Context().Value(requestIDKey).(string)
+	return id
+}
+
+// newRequestID generates a random UUIDv4 string.
+func newRequestID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "00000000-0000-0000-0000-000000000000"
+	}
+
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
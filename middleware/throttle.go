@@ -0,0 +1,26 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/dufuqiang/gorouter"
+)
+
+// Throttle returns a MiddlewareType that allows at most n requests to be
+// in flight at once, responding 503 Service Unavailable to any request
+// that arrives while the semaphore is full.
+func Throttle(n int) gorouter.MiddlewareType {
+	sem := make(chan struct{}, n)
+
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+				next(w, r)
+			default:
+				http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+			}
+		}
+	}
+}
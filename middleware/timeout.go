@@ -0,0 +1,89 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/dufuqiang/gorouter"
+)
+
+// Timeout returns a MiddlewareType that bounds how long the downstream
+// handler is given to respond, mirroring http.TimeoutHandler: next runs
+// with a context cancelled after d, and if it has not written a response
+// by the time the deadline passes, Timeout itself writes a 503 Service
+// Unavailable. A handler that does finish in time, whether or not it
+// bothered to check ctx.Err(), is unaffected. Because next keeps running
+// in its own goroutine after a timeout (there is no way to force it to
+// stop), it must still check ctx.Done() itself to avoid doing needless
+// work once the deadline has passed.
+func Timeout(d time.Duration) gorouter.MiddlewareType {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			ctx, cancel := context.WithTimeout(r.Context(), d)
+			defer cancel()
+
+			tw := &timeoutWriter{ResponseWriter: w}
+			done := make(chan struct{})
+
+			go func() {
+				next(tw, r.WithContext(ctx))
+				close(done)
+			}()
+
+			select {
+			case <-done:
+			case <-ctx.Done():
+				tw.mu.Lock()
+				defer tw.mu.Unlock()
+
+				if !tw.wroteHeader {
+					tw.timedOut = true
+					http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+				}
+			}
+		}
+	}
+}
+
+// timeoutWriter wraps an http.ResponseWriter so that Timeout can safely
+// write the 503 response itself if next hasn't written anything by the
+// deadline, without racing against next's goroutine, and so that next is
+// prevented from writing to the real ResponseWriter after that has
+// happened.
+type timeoutWriter struct {
+	http.ResponseWriter
+
+	mu          sync.Mutex
+	timedOut    bool
+	wroteHeader bool
+}
+
+func (tw *timeoutWriter) WriteHeader(code int) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	if tw.timedOut || tw.wroteHeader {
+		return
+	}
+
+	tw.wroteHeader = true
+	tw.ResponseWriter.WriteHeader(code)
+}
+
+func (tw *timeoutWriter) Write(p []byte) (int, error) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	if tw.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+
+	if !tw.wroteHeader {
+		tw.wroteHeader = true
+		tw.ResponseWriter.WriteHeader(http.StatusOK)
+	}
+
+	return tw.ResponseWriter.Write(p)
+}
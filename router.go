@@ -4,7 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net/http"
-	"regexp"
+	"path"
 	"strings"
 )
 
@@ -13,11 +13,13 @@ var (
 	idPattern      = `[\d]+`
 	idKey          = `id`
 	methods        = map[string]bool{
-		http.MethodGet:    true,
-		http.MethodPost:   true,
-		http.MethodPut:    true,
-		http.MethodDelete: true,
-		http.MethodPatch:  true,
+		http.MethodGet:     true,
+		http.MethodPost:    true,
+		http.MethodPut:     true,
+		http.MethodDelete:  true,
+		http.MethodPatch:   true,
+		http.MethodOptions: true,
+		http.MethodHead:    true,
 	}
 )
 
@@ -32,57 +34,190 @@ type (
 		middleware []MiddlewareType
 		// the tree routers
 		trees map[string]*Tree
+		// mounted external handlers, keyed by their full absolute prefix
+		mounts map[string]http.Handler
+		// named routes, keyed by name, valued by their full absolute path
+		// template, used to build URLs with Router.URL
+		names map[string]string
+		// cors is the CORS configuration registered via Router.Cors, or nil
+		// if Cors has not been called. It is consulted by ServeHTTP to
+		// answer preflight requests against the trees as they stand at
+		// request time, rather than a snapshot taken when Cors was called.
+		cors *CorsOptions
 		// Custom route not found handler
 		notFound http.HandlerFunc
+		// Custom method not allowed handler
+		methodNotAllowed http.HandlerFunc
 		// PanicHandler for handling panic.
 		PanicHandler func(w http.ResponseWriter, req *http.Request, err interface{})
+		// RedirectTrailingSlash, if true, redirects a request to the same
+		// path with its trailing slash added or removed when the request
+		// as received doesn't match but the alternative does.
+		RedirectTrailingSlash bool
+		// RedirectFixedPath, if true, redirects a request to the
+		// canonical path when the request as received doesn't match but a
+		// case-insensitive, cleaned (collapsing "//", "." and "..")
+		// version of it does.
+		RedirectFixedPath bool
 	}
 )
 
 // New returns a newly initialized Router object that implements the Router
 func New() *Router {
 	return &Router{
-		trees: make(map[string]*Tree),
+		trees:  make(map[string]*Tree),
+		mounts: make(map[string]http.Handler),
+		names:  make(map[string]string),
 	}
 }
 
 // GET adds the route `path` that matches a GET http method to
 // execute the `handle` http.HandlerFunc.
-func (router *Router) GET(path string, handle http.HandlerFunc) {
-	router.Handle(http.MethodGet, path, handle)
+func (router *Router) GET(path string, handle http.HandlerFunc) error {
+	return router.Handle(http.MethodGet, path, handle)
 }
 
 // POST adds the route `path` that matches a POST http method to
 // execute the `handle` http.HandlerFunc.
-func (router *Router) POST(path string, handle http.HandlerFunc) {
-	router.Handle(http.MethodPost, path, handle)
+func (router *Router) POST(path string, handle http.HandlerFunc) error {
+	return router.Handle(http.MethodPost, path, handle)
 }
 
 // DELETE adds the route `path` that matches a DELETE http method to
 // execute the `handle` http.HandlerFunc.
-func (router *Router) DELETE(path string, handle http.HandlerFunc) {
-	router.Handle(http.MethodDelete, path, handle)
+func (router *Router) DELETE(path string, handle http.HandlerFunc) error {
+	return router.Handle(http.MethodDelete, path, handle)
 }
 
 // PUT adds the route `path` that matches a PUT http method to
 // execute the `handle` http.HandlerFunc.
-func (router *Router) PUT(path string, handle http.HandlerFunc) {
-	router.Handle(http.MethodPut, path, handle)
+func (router *Router) PUT(path string, handle http.HandlerFunc) error {
+	return router.Handle(http.MethodPut, path, handle)
 }
 
 // PATCH adds the route `path` that matches a PATCH http method to
 // execute the `handle` http.HandlerFunc.
-func (router *Router) PATCH(path string, handle http.HandlerFunc) {
-	router.Handle(http.MethodPatch, path, handle)
+func (router *Router) PATCH(path string, handle http.HandlerFunc) error {
+	return router.Handle(http.MethodPatch, path, handle)
 }
 
-// Group define routes groups If there is a path prefix that use `prefix`
+// OPTIONS adds the route `path` that matches an OPTIONS http method to
+// execute the `handle` http.HandlerFunc.
+func (router *Router) OPTIONS(path string, handle http.HandlerFunc) error {
+	return router.Handle(http.MethodOptions, path, handle)
+}
+
+// HEAD adds the route `path` that matches a HEAD http method to
+// execute the `handle` http.HandlerFunc.
+func (router *Router) HEAD(path string, handle http.HandlerFunc) error {
+	return router.Handle(http.MethodHead, path, handle)
+}
+
+// Any registers `path` with the given `handle` for every http method known
+// to the router, useful for catch-all or proxy-style handlers. It returns
+// the first error encountered, if any, but still attempts every method.
+func (router *Router) Any(path string, handle http.HandlerFunc) error {
+	var firstErr error
+
+	for method := range methods {
+		if err := router.Handle(method, path, handle); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return firstErr
+}
+
+// Group define routes groups If there is a path prefix that use `prefix`.
+// The returned Router's middleware stack is a copy of the parent's, so
+// middleware registered on one side via Use after Group does not leak to
+// the other.
 func (router *Router) Group(prefix string) *Router {
 	return &Router{
-		prefix:     prefix,
+		prefix:     router.childPrefix(prefix),
 		trees:      router.trees,
-		middleware: router.middleware,
+		mounts:     router.mounts,
+		names:      router.names,
+		cors:       router.cors,
+		middleware: router.copyMiddleware(),
+	}
+}
+
+// Route creates a nested Router scoped under prefix and passes it to fn, so
+// routes and further nesting can be declared inline. Like Group, the
+// nested Router's middleware stack is copy-on-write with respect to the
+// parent's.
+func (router *Router) Route(prefix string, fn func(r *Router)) *Router {
+	nested := router.Group(prefix)
+	fn(nested)
+	return nested
+}
+
+// With returns a Router scoped to router's routes with mw appended to its
+// middleware stack, without mutating router itself. It is useful for
+// applying middleware to a single route or a short run of routes inline.
+func (router *Router) With(mw ...MiddlewareType) *Router {
+	middleware := router.copyMiddleware()
+	middleware = append(middleware, mw...)
+
+	return &Router{
+		prefix:     router.prefix,
+		trees:      router.trees,
+		mounts:     router.mounts,
+		names:      router.names,
+		cors:       router.cors,
+		middleware: middleware,
+	}
+}
+
+// Mount registers h to handle every request under prefix. Requests are
+// forwarded to h with prefix stripped from the URL path, and the matched
+// prefix is stored on the request context for retrieval via
+// GetMountedPrefix. It returns an error, rather than mounting, if any
+// route is already registered under prefix: ServeHTTP matches mounts
+// before tree routes, so such a route would become permanently
+// unreachable.
+func (router *Router) Mount(prefix string, h http.Handler) error {
+	absolute := router.childPrefix(prefix)
+
+	for method, tree := range router.trees {
+		for _, path := range tree.Routes() {
+			if mountShadowsPath(absolute, path) {
+				return fmt.Errorf("gorouter: cannot mount %q: %s route %q would become unreachable", prefix, method, path)
+			}
+		}
+	}
+
+	router.mounts[absolute] = h
+	return nil
+}
+
+// mountShadowsPath reports whether a mount registered under mountPrefix
+// (as stored in router.mounts, without its leading slash) would intercept
+// requests to path ahead of tree lookup - the same test matchMount
+// performs at request time.
+func mountShadowsPath(mountPrefix, path string) bool {
+	return path == "/"+mountPrefix || strings.HasPrefix(path, "/"+mountPrefix+"/")
+}
+
+// childPrefix resolves prefix against router's own prefix, producing the
+// absolute prefix a nested Router or Mount should register under.
+func (router *Router) childPrefix(prefix string) string {
+	prefix = strings.TrimPrefix(prefix, "/")
+
+	if router.prefix == "" {
+		return prefix
 	}
+
+	return strings.TrimSuffix(router.prefix, "/") + "/" + prefix
+}
+
+// copyMiddleware returns a copy of router's middleware stack, so appending
+// to the copy never mutates router's own slice.
+func (router *Router) copyMiddleware() []MiddlewareType {
+	middleware := make([]MiddlewareType, len(router.middleware))
+	copy(middleware, router.middleware)
+	return middleware
 }
 
 // NotFoundFunc registers a handler when the request route is not found
@@ -90,23 +225,52 @@ func (router *Router) NotFoundFunc(handler http.HandlerFunc) {
 	router.notFound = handler
 }
 
-// Handle registers a new request handle with the given path and method.
-func (router *Router) Handle(method string, path string, handle http.HandlerFunc) {
+// MethodNotAllowedFunc registers a handler for requests whose path is
+// registered under another http method but not under the one requested.
+func (router *Router) MethodNotAllowedFunc(handler http.HandlerFunc) {
+	router.methodNotAllowed = handler
+}
+
+// Handle registers a new request handle with the given path and method. It
+// returns an error, instead of panicking, if path conflicts with an
+// already-registered route, or if path falls under a prefix already
+// registered with Mount, since ServeHTTP matches mounts before tree
+// routes and the new route would never be reached.
+func (router *Router) Handle(method string, path string, handle http.HandlerFunc) error {
 	if _, ok := methods[method]; !ok {
 		panic(fmt.Errorf("invalid method"))
 	}
 
+	full := router.fullPath(path)
+
+	for mountPrefix := range router.mounts {
+		if mountShadowsPath(mountPrefix, full) {
+			return fmt.Errorf("gorouter: %q conflicts with a mount already registered at %q", full, mountPrefix)
+		}
+	}
+
 	root := router.trees[method]
 	if root == nil {
 		root = NewTree()
 		router.trees[method] = root
 	}
 
-	if router.prefix != "" {
-		path = router.prefix + "/" + path
+	return root.Add(full, handle, router.middleware...)
+}
+
+// fullPath resolves path against router's own prefix, producing the
+// absolute, single "/"-rooted path it should be registered under. path's
+// own leading slash, if any, is stripped before joining so nested routers
+// compose correctly regardless of whether callers write "users" or
+// "/users".
+func (router *Router) fullPath(path string) string {
+	path = strings.TrimPrefix(path, "/")
+
+	if router.prefix == "" {
+		return "/" + path
 	}
 
-	root.Add(path, handle, router.middleware...)
+	return "/" + router.prefix + "/" + path
 }
 
 // GetParam return route param stored in r.
@@ -133,6 +297,49 @@ func GetAllParams(r *http.Request) paramsMapType {
 	return nil
 }
 
+// mountPrefixKeyType is a private struct that is used for storing the
+// prefix matched by Router.Mount in the request context
+type mountPrefixKeyType struct{}
+
+// mountPrefixKey is the key that is used to store the matched mount prefix
+// in the net.Context for each request
+var mountPrefixKey = mountPrefixKeyType{}
+
+// GetMountedPrefix returns the prefix matched by Router.Mount for r, or ""
+// if r was not served through a mounted handler.
+func GetMountedPrefix(r *http.Request) string {
+	prefix, _ := r.Context().Value(mountPrefixKey).(string)
+	return prefix
+}
+
+// matchMount returns the most specific mount registered under router whose
+// prefix contains requestUrl, along with the path that should be passed on
+// to its handler once the prefix is stripped.
+func (router *Router) matchMount(requestUrl string) (h http.Handler, prefix string, remainder string, found bool) {
+	for candidate, handler := range router.mounts {
+		if requestUrl != "/"+candidate && !strings.HasPrefix(requestUrl, "/"+candidate+"/") {
+			continue
+		}
+
+		if len(candidate) < len(prefix) {
+			continue
+		}
+
+		h, prefix, found = handler, candidate, true
+	}
+
+	if !found {
+		return nil, "", "", false
+	}
+
+	remainder = strings.TrimPrefix(requestUrl, "/"+prefix)
+	if remainder == "" {
+		remainder = "/"
+	}
+
+	return h, prefix, remainder, true
+}
+
 // ServeHTTP makes the router implement the http.Handler interface.
 func (router *Router) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	requestUrl := r.URL.Path
@@ -145,50 +352,130 @@ func (router *Router) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		}()
 	}
 
-	if _, ok := router.trees[r.Method]; !ok {
-		panic(fmt.Errorf("Error method or method is not registered "))
+	if h, prefix, remainder, ok := router.matchMount(requestUrl); ok {
+		ctx := context.WithValue(r.Context(), mountPrefixKey, prefix)
+		mounted := r.WithContext(ctx)
+		url := *r.URL
+		url.Path = remainder
+		mounted.URL = &url
+		h.ServeHTTP(w, mounted)
+		return
 	}
 
-	nodes := router.trees[r.Method].Find(requestUrl, 0)
-
-	if len(nodes) > 0 {
-		node := nodes[0]
+	if router.handlePreflight(w, r, requestUrl) {
+		return
+	}
 
-		if node.handle != nil {
-			if node.path == requestUrl {
-				handle(w, r, node.handle, node.middleware)
-				return
+	if tree, ok := router.trees[r.Method]; ok {
+		if handler, middleware, _, params, found := tree.Lookup(requestUrl); found {
+			if params != nil {
+				ctx := context.WithValue(r.Context(), contextKey, params)
+				r = r.WithContext(ctx)
+				defer tree.PutParams(params)
 			}
+			handle(w, r, handler, middleware)
+			return
+		}
 
-			if node.path == requestUrl[1:] {
-				handle(w, r, node.handle, node.middleware)
+		if router.RedirectTrailingSlash || router.RedirectFixedPath {
+			if fixedPath, ok := router.recoverPath(tree, requestUrl); ok {
+				router.redirect(w, r, fixedPath)
 				return
 			}
 		}
 	}
 
-	if len(nodes) == 0 {
-		res := strings.Split(requestUrl, "/")
-		prefix := res[1]
+	if allow := router.allowedMethods(requestUrl); len(allow) > 0 {
+		router.HandleMethodNotAllowed(w, r, allow)
+		return
+	}
 
-		nodes := router.trees[r.Method].Find(prefix, 1)
+	router.HandleNotFound(w, r, router.middleware)
+}
 
-		for _, node := range nodes {
-			handler := node.handle
+// recoverPath attempts to recover a usable path for requestUrl in tree
+// using the RedirectTrailingSlash and RedirectFixedPath options. It first
+// tries an exact, case-sensitive trailing-slash toggle, then a
+// case-insensitive fixed-path match - which, when RedirectTrailingSlash
+// is also enabled, tolerates a mismatched trailing slash as part of the
+// same fold-matching walk, so a request that is both wrong-case and
+// trailing-slash-mismatched is still corrected in a single redirect
+// rather than needing each correction to independently match the
+// unmodified request.
+func (router *Router) recoverPath(tree *Tree, requestUrl string) (string, bool) {
+	if router.RedirectTrailingSlash {
+		var alt string
+		if strings.HasSuffix(requestUrl, "/") {
+			alt = strings.TrimSuffix(requestUrl, "/")
+		} else {
+			alt = requestUrl + "/"
+		}
 
-			if handler != nil && node.path != requestUrl {
+		if alt != "" && tree.Match(alt) {
+			return alt, true
+		}
+	}
 
-				if matchParamsMap, ok := router.matchAndParse(requestUrl, node.path); ok {
-					ctx := context.WithValue(r.Context(), contextKey, matchParamsMap)
-					r = r.WithContext(ctx)
-					handle(w, r, handler, node.middleware)
-					return
-				}
-			}
+	if router.RedirectFixedPath {
+		if fixed, ok := tree.LookupCaseInsensitive(cleanPath(requestUrl), router.RedirectTrailingSlash); ok {
+			return fixed, true
 		}
 	}
 
-	router.HandleNotFound(w, r, router.middleware)
+	return "", false
+}
+
+// redirect sends r to target, preserving its query string. It uses 301
+// for GET/HEAD, which browsers and caches treat as safe to follow and
+// cache, and 308 for any other method, which preserves the request body
+// and method on the follow-up request.
+func (router *Router) redirect(w http.ResponseWriter, r *http.Request, target string) {
+	if r.URL.RawQuery != "" {
+		target += "?" + r.URL.RawQuery
+	}
+
+	status := http.StatusMovedPermanently
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		status = http.StatusPermanentRedirect
+	}
+
+	http.Redirect(w, r, target, status)
+}
+
+// cleanPath canonicalizes p, collapsing "//", "." and ".." like
+// path.Clean, while preserving a trailing slash p may have had.
+func cleanPath(p string) string {
+	if p == "" {
+		return "/"
+	}
+
+	cleaned := path.Clean(p)
+
+	if !strings.HasPrefix(cleaned, "/") {
+		cleaned = "/" + cleaned
+	}
+
+	if strings.HasSuffix(p, "/") && !strings.HasSuffix(cleaned, "/") {
+		cleaned += "/"
+	}
+
+	return cleaned
+}
+
+// allowedMethods returns every http method that has a registered route
+// matching requestUrl. It does not exclude the requested method; ServeHTTP
+// only calls it once the requested method's tree has failed to match. It
+// is used to build the `Allow:` header on a 405 response.
+func (router *Router) allowedMethods(requestUrl string) []string {
+	var allowed []string
+
+	for method, tree := range router.trees {
+		if tree.Match(requestUrl) {
+			allowed = append(allowed, method)
+		}
+	}
+
+	return allowed
 }
 
 // Use appends a middleware handler to the middleware stack.
@@ -207,6 +494,22 @@ func (router *Router) HandleNotFound(w http.ResponseWriter, r *http.Request, mid
 	http.NotFound(w, r)
 }
 
+// HandleMethodNotAllowed responds to a request whose path is registered
+// but not under the requested method, setting the `Allow:` header to the
+// methods that are actually registered for that path and then calling the
+// handler set with MethodNotAllowedFunc, or writing a plain 405 if none is
+// set.
+func (router *Router) HandleMethodNotAllowed(w http.ResponseWriter, r *http.Request, allow []string) {
+	w.Header().Set("Allow", strings.Join(allow, ", "))
+
+	if router.methodNotAllowed != nil {
+		handle(w, r, router.methodNotAllowed, router.middleware)
+		return
+	}
+
+	http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+}
+
 // handle execute middleware chain
 func handle(w http.ResponseWriter, r *http.Request, handler http.HandlerFunc, middleware []MiddlewareType) {
 	var baseHandler = handler
@@ -216,69 +519,3 @@ func handle(w http.ResponseWriter, r *http.Request, handler http.HandlerFunc, mi
 	baseHandler(w, r)
 }
 
-// Match check if the request match the route Pattern
-func (router *Router) Match(requestUrl string, path string) bool {
-	_, ok := router.matchAndParse(requestUrl, path)
-	return ok
-}
-
-// matchAndParse check if the request matches the route path and returns a map of the parsed
-func (router *Router) matchAndParse(requestUrl string, path string) (paramsMapType, bool) {
-	res := strings.Split(path, "/")
-
-	var (
-		matchName []string
-		sTemp     string
-	)
-
-	matchParams := make(paramsMapType)
-
-	for _, str := range res {
-
-		if str == "" {
-			continue
-		}
-
-		strLen := len(str)
-		firstChar := str[0]
-		lastChar := str[strLen-1]
-
-		if string(firstChar) == "{" && string(lastChar) == "}" {
-			matchStr := string(str[1 : strLen-1])
-			res := strings.Split(matchStr, ":")
-
-			matchName = append(matchName, res[0])
-
-			sTemp = sTemp + "/" + "(" + res[1] + ")"
-		} else if string(firstChar) == ":" {
-			matchStr := str
-			res := strings.Split(matchStr, ":")
-			matchName = append(matchName, res[1])
-
-			if res[1] == idKey {
-				sTemp = sTemp + "/" + "(" + idPattern + ")"
-			} else {
-				sTemp = sTemp + "/" + "(" + defaultPattern + ")"
-			}
-		} else {
-			sTemp = sTemp + "/" + str
-		}
-	}
-
-	pattern := sTemp
-
-	re := regexp.MustCompile(pattern)
-	subMatch := re.FindSubmatch([]byte(requestUrl))
-
-	if subMatch != nil {
-		if string(subMatch[0]) == requestUrl {
-			subMatch = subMatch[1:]
-			for k, v := range subMatch {
-				matchParams[matchName[k]] = string(v)
-			}
-			return matchParams, true
-		}
-	}
-
-	return nil, false
-}
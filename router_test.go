@@ -0,0 +1,187 @@
+package gorouter
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRedirectTrailingSlash(t *testing.T) {
+	router := New()
+	router.RedirectTrailingSlash = true
+	router.GET("/users", noopHandler)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/users/", nil))
+
+	if rec.Code != http.StatusMovedPermanently {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusMovedPermanently)
+	}
+
+	if got := rec.Header().Get("Location"); got != "/users" {
+		t.Fatalf("Location = %q, want %q", got, "/users")
+	}
+}
+
+func TestRedirectTrailingSlashDisabledByDefault(t *testing.T) {
+	router := New()
+	router.GET("/users", noopHandler)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/users/", nil))
+
+	if rec.Code == http.StatusMovedPermanently {
+		t.Fatalf("trailing-slash redirect fired despite being disabled by default")
+	}
+}
+
+func TestRedirectFixedPath(t *testing.T) {
+	router := New()
+	router.RedirectFixedPath = true
+	router.GET("/Users/:id", noopHandler)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "//users/42", nil))
+
+	if rec.Code != http.StatusMovedPermanently {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusMovedPermanently)
+	}
+
+	if got := rec.Header().Get("Location"); got != "/Users/42" {
+		t.Fatalf("Location = %q, want %q", got, "/Users/42")
+	}
+}
+
+func TestRouteNestedPath(t *testing.T) {
+	router := New()
+	router.Route("/api", func(r *Router) {
+		r.GET("/users", noopHandler)
+	})
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/users", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+}
+
+func TestGroupNestedPath(t *testing.T) {
+	router := New()
+	api := router.Group("/api")
+	api.GET("/users", noopHandler)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/users", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+
+	if got := rec.Body.String(); got != "" {
+		t.Fatalf("body = %q, want empty", got)
+	}
+}
+
+func TestRouteDoubleNestedPath(t *testing.T) {
+	router := New()
+	router.Route("/api", func(r *Router) {
+		r.Route("/v1", func(r *Router) {
+			r.GET("/users", noopHandler)
+		})
+	})
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/users", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+}
+
+func TestRedirectFixedPathAndTrailingSlashTogether(t *testing.T) {
+	router := New()
+	router.RedirectTrailingSlash = true
+	router.RedirectFixedPath = true
+	router.GET("/Users/:id", noopHandler)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/users/42/", nil))
+
+	if rec.Code != http.StatusMovedPermanently {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusMovedPermanently)
+	}
+
+	if got := rec.Header().Get("Location"); got != "/Users/42" {
+		t.Fatalf("Location = %q, want %q", got, "/Users/42")
+	}
+}
+
+func TestMountRejectsAlreadyRegisteredRoute(t *testing.T) {
+	router := New()
+	router.GET("/admin/settings", noopHandler)
+
+	sub := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("mounted:" + r.URL.Path))
+	})
+
+	if err := router.Mount("/admin", sub); err == nil {
+		t.Fatalf("Mount() error = nil, want an error since /admin/settings is already registered")
+	}
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/settings", nil))
+
+	if got := rec.Body.String(); got != "" {
+		t.Fatalf("body = %q, want the GET route to still be reachable", got)
+	}
+}
+
+func TestHandleRejectsRouteUnderExistingMount(t *testing.T) {
+	router := New()
+
+	sub := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("mounted:" + r.URL.Path))
+	})
+
+	if err := router.Mount("/admin", sub); err != nil {
+		t.Fatalf("Mount() error = %v", err)
+	}
+
+	if err := router.GET("/admin/settings", noopHandler); err == nil {
+		t.Fatalf("GET() error = nil, want an error since /admin is already mounted")
+	}
+}
+
+func TestMountAllowsUnrelatedRoutes(t *testing.T) {
+	router := New()
+	router.GET("/users", noopHandler)
+
+	sub := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("mounted:" + r.URL.Path))
+	})
+
+	if err := router.Mount("/admin", sub); err != nil {
+		t.Fatalf("Mount() error = %v", err)
+	}
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/settings", nil))
+
+	if got := rec.Body.String(); got != "mounted:/settings" {
+		t.Fatalf("body = %q, want %q", got, "mounted:/settings")
+	}
+}
+
+func TestRedirectPreservesQueryString(t *testing.T) {
+	router := New()
+	router.RedirectTrailingSlash = true
+	router.GET("/users", noopHandler)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/users/?page=2", nil))
+
+	if got := rec.Header().Get("Location"); got != "/users?page=2" {
+		t.Fatalf("Location = %q, want %q", got, "/users?page=2")
+	}
+}
@@ -0,0 +1,506 @@
+package gorouter
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// nodeKind identifies what a Tree node matches against the path.
+type nodeKind uint8
+
+const (
+	staticNode nodeKind = iota
+	paramNode
+	catchAllNode
+)
+
+// node is a single node of a Tree's radix structure. A staticNode holds the
+// literal text shared by its children, split at insertion time whenever a
+// newly inserted path diverges partway through an existing prefix. A
+// paramNode matches exactly one path segment, optionally constrained by
+// pattern. A catchAllNode matches the remainder of the path and is always
+// a leaf.
+type node struct {
+	kind    nodeKind
+	prefix  string
+	name    string
+	pattern *regexp.Regexp
+
+	staticChildren []*node
+	paramChild     *node
+	catchAllChild  *node
+
+	path       string
+	handle     http.HandlerFunc
+	middleware []MiddlewareType
+}
+
+// Tree is a radix tree mapping the path templates registered for a single
+// http method to their handler. Lookup walks the tree once per request,
+// extracting params into a map drawn from a sync.Pool so that static and
+// lightly-parameterized routes avoid per-request allocations; only a
+// segment carrying an explicit `{name:regex}` constraint falls back to
+// regexp.
+type Tree struct {
+	root *node
+
+	paramsPool sync.Pool
+	maxParams  int
+}
+
+// NewTree returns an empty Tree.
+func NewTree() *Tree {
+	tree := &Tree{root: &node{kind: staticNode}}
+	tree.paramsPool.New = func() interface{} {
+		return make(paramsMapType, tree.maxParams)
+	}
+	return tree
+}
+
+// Add registers handle under path, wrapping it with the given middleware
+// chain. It returns an error, rather than panicking, if path conflicts
+// with an already-registered route (e.g. two different param names at the
+// same position, or the exact same path registered twice).
+func (t *Tree) Add(path string, handle http.HandlerFunc, middleware ...MiddlewareType) error {
+	if err := t.root.insert(path, path, handle, middleware); err != nil {
+		return err
+	}
+
+	if n := countParams(path); n > t.maxParams {
+		t.maxParams = n
+	}
+
+	return nil
+}
+
+// Lookup finds the handler registered for requestPath. On a match it
+// returns the handler, its middleware chain, the path template it was
+// registered under and a params map drawn from the Tree's pool - callers
+// must return it via Tree.PutParams once they are done with it. The params
+// map is nil when the matched template contains no param or catch-all
+// segments.
+func (t *Tree) Lookup(requestPath string) (handle http.HandlerFunc, middleware []MiddlewareType, path string, params paramsMapType, ok bool) {
+	n := t.root
+	remaining := requestPath
+
+walk:
+	for {
+		if len(remaining) > len(n.prefix) {
+			if remaining[:len(n.prefix)] != n.prefix {
+				return nil, nil, "", nil, false
+			}
+			remaining = remaining[len(n.prefix):]
+		} else if remaining == n.prefix {
+			if n.handle == nil {
+				return nil, nil, "", nil, false
+			}
+			return n.handle, n.middleware, n.path, params, true
+		} else {
+			return nil, nil, "", nil, false
+		}
+
+		if remaining == "" {
+			return nil, nil, "", nil, false
+		}
+
+		for _, child := range n.staticChildren {
+			if len(child.prefix) > 0 && child.prefix[0] == remaining[0] {
+				n = child
+				continue walk
+			}
+		}
+
+		if n.paramChild != nil {
+			end := 0
+			for end < len(remaining) && remaining[end] != '/' {
+				end++
+			}
+
+			segment := remaining[:end]
+
+			if n.paramChild.pattern == nil || n.paramChild.pattern.MatchString(segment) {
+				if params == nil {
+					params = t.paramsPool.Get().(paramsMapType)
+				}
+				params[n.paramChild.name] = segment
+
+				n = n.paramChild
+				remaining = remaining[end:]
+				continue walk
+			}
+		}
+
+		if n.catchAllChild != nil {
+			if params == nil {
+				params = t.paramsPool.Get().(paramsMapType)
+			}
+			params[n.catchAllChild.name] = remaining
+
+			return n.catchAllChild.handle, n.catchAllChild.middleware, n.catchAllChild.path, params, n.catchAllChild.handle != nil
+		}
+
+		return nil, nil, "", nil, false
+	}
+}
+
+// PutParams clears and returns params to the Tree's pool. Callers must not
+// use params after calling PutParams.
+func (t *Tree) PutParams(params paramsMapType) {
+	if params == nil {
+		return
+	}
+
+	for k := range params {
+		delete(params, k)
+	}
+
+	t.paramsPool.Put(params)
+}
+
+// LookupCaseInsensitive reports whether requestPath matches a registered
+// route once static segments are compared case-insensitively, returning
+// the canonically-cased path the caller should be redirected to. If
+// allowTrailingSlash is true, a requestPath with one extra or one missing
+// trailing slash relative to the registered route is tolerated as part of
+// the same fold-matching walk, so a request that is both wrong-case and
+// trailing-slash-mismatched is still corrected in one redirect, rather
+// than needing RedirectFixedPath and RedirectTrailingSlash to each
+// independently match the unmodified request. It does not itself resolve
+// a handler; the follow-up request made to the fixed path resolves
+// normally through Lookup.
+func (t *Tree) LookupCaseInsensitive(requestPath string, allowTrailingSlash bool) (fixedPath string, found bool) {
+	return t.root.lookupFold(requestPath, allowTrailingSlash)
+}
+
+// lookupFold is the case-insensitive counterpart to node.insert/Lookup's
+// matching walk: it tries every static child whose prefix folds to a
+// match (rather than pruning by first byte, since case-folding means
+// distinct byte values can be equivalent) and rebuilds the canonically
+// cased path as it unwinds. When allowTrailingSlash is true, it also
+// accepts a dangling "/" left over at a node that itself has a handle
+// (request has an extra trailing slash) and, symmetrically, a node with
+// no handle of its own whose "/"-prefixed static child does (request is
+// missing one).
+func (n *node) lookupFold(remaining string, allowTrailingSlash bool) (fixedSuffix string, ok bool) {
+	if len(remaining) < len(n.prefix) || !strings.EqualFold(remaining[:len(n.prefix)], n.prefix) {
+		return "", false
+	}
+
+	consumed := n.prefix
+	remaining = remaining[len(n.prefix):]
+
+	if remaining == "" {
+		if n.handle != nil {
+			return consumed, true
+		}
+
+		if allowTrailingSlash {
+			for _, child := range n.staticChildren {
+				if child.prefix == "/" && child.handle != nil {
+					return consumed + "/", true
+				}
+			}
+		}
+
+		return "", false
+	}
+
+	if allowTrailingSlash && remaining == "/" && n.handle != nil {
+		return consumed, true
+	}
+
+	for _, child := range n.staticChildren {
+		if suffix, ok := child.lookupFold(remaining, allowTrailingSlash); ok {
+			return consumed + suffix, true
+		}
+	}
+
+	if n.paramChild != nil {
+		end := 0
+		for end < len(remaining) && remaining[end] != '/' {
+			end++
+		}
+
+		segment := remaining[:end]
+
+		if n.paramChild.pattern == nil || n.paramChild.pattern.MatchString(segment) {
+			if suffix, ok := n.paramChild.lookupFold(remaining[end:], allowTrailingSlash); ok {
+				return consumed + segment + suffix, true
+			}
+		}
+	}
+
+	if n.catchAllChild != nil && n.catchAllChild.handle != nil {
+		return consumed + remaining, true
+	}
+
+	return "", false
+}
+
+// Routes returns the path template of every route registered in the tree.
+func (t *Tree) Routes() []string {
+	var routes []string
+	t.root.collectRoutes(&routes)
+	return routes
+}
+
+// collectRoutes appends the path template of n and every descendant that
+// carries a handler to routes.
+func (n *node) collectRoutes(routes *[]string) {
+	if n.handle != nil {
+		*routes = append(*routes, n.path)
+	}
+
+	for _, child := range n.staticChildren {
+		child.collectRoutes(routes)
+	}
+
+	if n.paramChild != nil {
+		n.paramChild.collectRoutes(routes)
+	}
+
+	if n.catchAllChild != nil {
+		n.catchAllChild.collectRoutes(routes)
+	}
+}
+
+// Match reports whether requestPath matches a route registered in the
+// tree with a non-nil handler. It is used to build the
+// `Allow:` header of a 405 response without needing a full Lookup.
+func (t *Tree) Match(requestPath string) bool {
+	handle, _, _, params, ok := t.Lookup(requestPath)
+	t.PutParams(params)
+	return ok && handle != nil
+}
+
+// insert adds fullPath (with handle and middleware) to the subtree rooted
+// at n, where remaining is the portion of fullPath not yet consumed by an
+// ancestor's prefix.
+func (n *node) insert(remaining, fullPath string, handle http.HandlerFunc, middleware []MiddlewareType) error {
+	i := longestCommonPrefix(remaining, n.prefix)
+
+	if i < len(n.prefix) {
+		n.split(i)
+	}
+
+	if i == len(remaining) {
+		if n.handle != nil {
+			return fmt.Errorf("gorouter: %q conflicts with an already-registered route", fullPath)
+		}
+
+		n.handle = handle
+		n.middleware = middleware
+		n.path = fullPath
+		return nil
+	}
+
+	return n.insertChild(remaining[i:], fullPath, handle, middleware)
+}
+
+// split breaks n into a parent retaining only its first i bytes of prefix
+// and a new child carrying the rest of n's original prefix, along with n's
+// existing children and terminal handler.
+func (n *node) split(i int) {
+	child := &node{
+		kind:           staticNode,
+		prefix:         n.prefix[i:],
+		staticChildren: n.staticChildren,
+		paramChild:     n.paramChild,
+		catchAllChild:  n.catchAllChild,
+		path:           n.path,
+		handle:         n.handle,
+		middleware:     n.middleware,
+	}
+
+	n.prefix = n.prefix[:i]
+	n.staticChildren = []*node{child}
+	n.paramChild = nil
+	n.catchAllChild = nil
+	n.path = ""
+	n.handle = nil
+	n.middleware = nil
+}
+
+// insertChild finds or creates the appropriate child of n for remaining,
+// which is known to be non-empty, and continues insertion there.
+func (n *node) insertChild(remaining, fullPath string, handle http.HandlerFunc, middleware []MiddlewareType) error {
+	token, start, kind := findWildcard(remaining)
+
+	if kind == paramNode && start == 0 {
+		name, pattern := parseParamToken(token)
+		rest := remaining[len(token):]
+
+		if n.paramChild == nil {
+			n.paramChild = &node{kind: paramNode, name: name, pattern: pattern}
+		} else if n.paramChild.name != name {
+			return fmt.Errorf("gorouter: %q conflicts with param %q already registered at this position", fullPath, n.paramChild.name)
+		} else if !samePattern(n.paramChild.pattern, pattern) {
+			return fmt.Errorf("gorouter: %q conflicts with param %q already registered at this position with a different pattern", fullPath, n.paramChild.name)
+		}
+
+		return n.paramChild.insert(rest, fullPath, handle, middleware)
+	}
+
+	if kind == catchAllNode && start == 0 {
+		name := token[1:]
+
+		if n.catchAllChild == nil {
+			n.catchAllChild = &node{kind: catchAllNode, name: name}
+		} else if n.catchAllChild.name != name {
+			return fmt.Errorf("gorouter: %q conflicts with catch-all %q already registered at this position", fullPath, n.catchAllChild.name)
+		}
+
+		if n.catchAllChild.handle != nil {
+			return fmt.Errorf("gorouter: %q conflicts with an already-registered route", fullPath)
+		}
+
+		n.catchAllChild.handle = handle
+		n.catchAllChild.middleware = middleware
+		n.catchAllChild.path = fullPath
+		return nil
+	}
+
+	for _, child := range n.staticChildren {
+		if len(child.prefix) > 0 && child.prefix[0] == remaining[0] {
+			return child.insert(remaining, fullPath, handle, middleware)
+		}
+	}
+
+	staticPart := remaining
+	if start >= 0 {
+		staticPart = remaining[:start]
+	}
+
+	child := &node{kind: staticNode, prefix: staticPart}
+	n.staticChildren = append(n.staticChildren, child)
+
+	if staticPart == remaining {
+		child.handle = handle
+		child.middleware = middleware
+		child.path = fullPath
+		return nil
+	}
+
+	return child.insertChild(remaining[len(staticPart):], fullPath, handle, middleware)
+}
+
+// findWildcard reports the next `:name`, `{name:regex}` or `*name` token in
+// path, the byte offset it starts at, and which kind of node it describes.
+// It returns start -1 and kind staticNode if path contains no wildcard.
+func findWildcard(path string) (token string, start int, kind nodeKind) {
+	for i := 0; i < len(path); i++ {
+		switch path[i] {
+		case ':':
+			end := i + 1
+			for end < len(path) && path[end] != '/' {
+				end++
+			}
+			return path[i:end], i, paramNode
+		case '{':
+			end := i + 1
+			for end < len(path) && path[end] != '}' {
+				end++
+			}
+			if end == len(path) {
+				return path[i:], i, paramNode
+			}
+			return path[i : end+1], i, paramNode
+		case '*':
+			return path[i:], i, catchAllNode
+		}
+	}
+
+	return "", -1, staticNode
+}
+
+// parseParamToken extracts the param name and, if present, the regexp
+// pattern constraining it from a `:name` or `{name:regex}` token. A bare
+// `:id` is constrained to idPattern and any other bare `:name` to
+// defaultPattern, matching the constraints the router has always applied.
+func parseParamToken(token string) (name string, pattern *regexp.Regexp) {
+	if token[0] == '{' {
+		inner := token[1 : len(token)-1]
+
+		name = inner
+		rawPattern := ""
+
+		if idx := indexByte(inner, ':'); idx >= 0 {
+			name = inner[:idx]
+			rawPattern = inner[idx+1:]
+		}
+
+		if rawPattern == "" {
+			rawPattern = defaultPattern
+		}
+
+		return name, regexp.MustCompile("^" + rawPattern + "$")
+	}
+
+	name = token[1:]
+
+	if name == idKey {
+		return name, regexp.MustCompile("^" + idPattern + "$")
+	}
+
+	return name, regexp.MustCompile("^" + defaultPattern + "$")
+}
+
+// samePattern reports whether a and b constrain a param segment identically.
+// *regexp.Regexp has no meaningful ==, so patterns are compared by the
+// source they were compiled from; both are always non-nil in practice,
+// since parseParamToken never returns a nil pattern.
+func samePattern(a, b *regexp.Regexp) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return a.String() == b.String()
+}
+
+// indexByte returns the index of the first occurrence of c in s, or -1.
+func indexByte(s string, c byte) int {
+	for i := 0; i < len(s); i++ {
+		if s[i] == c {
+			return i
+		}
+	}
+	return -1
+}
+
+// countParams returns the number of param and catch-all segments in path.
+func countParams(path string) int {
+	n := 0
+
+	for {
+		token, start, kind := findWildcard(path)
+		if start < 0 {
+			return n
+		}
+
+		n++
+
+		if kind == catchAllNode {
+			return n
+		}
+
+		path = path[start+len(token):]
+	}
+}
+
+// longestCommonPrefix returns the length of the longest common byte prefix
+// of a and b.
+func longestCommonPrefix(a, b string) int {
+	max := len(a)
+	if len(b) < max {
+		max = len(b)
+	}
+
+	i := 0
+	for i < max && a[i] == b[i] {
+		i++
+	}
+	return i
+}
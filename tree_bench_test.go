@@ -0,0 +1,56 @@
+package gorouter
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+// buildBenchTree registers numStatic static routes and numParam
+// single-param routes, mirroring a mid-size API surface.
+func buildBenchTree(numStatic, numParam int) *Tree {
+	tree := NewTree()
+	noop := func(w http.ResponseWriter, r *http.Request) {}
+
+	for i := 0; i < numStatic; i++ {
+		_ = tree.Add(fmt.Sprintf("/static/resource%d/items", i), noop)
+	}
+
+	for i := 0; i < numParam; i++ {
+		_ = tree.Add(fmt.Sprintf("/param/resource%d/:id", i), noop)
+	}
+
+	return tree
+}
+
+func BenchmarkTreeLookupStatic(b *testing.B) {
+	tree := buildBenchTree(1000, 500)
+	path := "/static/resource999/items"
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		_, _, _, params, ok := tree.Lookup(path)
+		if !ok {
+			b.Fatalf("expected %q to match", path)
+		}
+		tree.PutParams(params)
+	}
+}
+
+func BenchmarkTreeLookupParam(b *testing.B) {
+	tree := buildBenchTree(1000, 500)
+	path := "/param/resource499/42"
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		_, _, _, params, ok := tree.Lookup(path)
+		if !ok {
+			b.Fatalf("expected %q to match", path)
+		}
+		tree.PutParams(params)
+	}
+}
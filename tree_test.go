@@ -0,0 +1,123 @@
+package gorouter
+
+import (
+	"net/http"
+	"testing"
+)
+
+func noop(w http.ResponseWriter, r *http.Request) {}
+
+func TestTreeAddDuplicatePathConflicts(t *testing.T) {
+	tree := NewTree()
+
+	if err := tree.Add("/users/:id", noop); err != nil {
+		t.Fatalf("first Add: unexpected error: %v", err)
+	}
+
+	if err := tree.Add("/users/:id", noop); err == nil {
+		t.Fatalf("second Add: expected a conflict error, got nil")
+	}
+}
+
+func TestTreeAddParamNameConflicts(t *testing.T) {
+	tree := NewTree()
+
+	if err := tree.Add("/users/:id/profile", noop); err != nil {
+		t.Fatalf("first Add: unexpected error: %v", err)
+	}
+
+	if err := tree.Add("/users/:slug/settings", noop); err == nil {
+		t.Fatalf("expected a conflict error for differing param names, got nil")
+	}
+}
+
+func TestTreeAddParamPatternConflicts(t *testing.T) {
+	tree := NewTree()
+
+	if err := tree.Add("/users/:id/profile", noop); err != nil {
+		t.Fatalf("first Add: unexpected error: %v", err)
+	}
+
+	if err := tree.Add("/users/{id:[a-z]+}/settings", noop); err == nil {
+		t.Fatalf("expected a conflict error for differing patterns on the same param name, got nil")
+	}
+
+	if _, _, _, _, found := tree.Lookup("/users/abc/settings"); found {
+		t.Fatalf("the conflicting route must not have been registered")
+	}
+}
+
+func TestTreeAddSamePatternDoesNotConflict(t *testing.T) {
+	tree := NewTree()
+
+	if err := tree.Add("/users/:id/profile", noop); err != nil {
+		t.Fatalf("first Add: unexpected error: %v", err)
+	}
+
+	if err := tree.Add("/users/:id/settings", noop); err != nil {
+		t.Fatalf("second Add: unexpected error registering the same param name/pattern again: %v", err)
+	}
+}
+
+func TestTreeInsertSplitsOnDivergence(t *testing.T) {
+	tree := NewTree()
+
+	if err := tree.Add("/users/profile", noop); err != nil {
+		t.Fatalf("Add /users/profile: unexpected error: %v", err)
+	}
+
+	if err := tree.Add("/users/settings", noop); err != nil {
+		t.Fatalf("Add /users/settings: unexpected error: %v", err)
+	}
+
+	for _, path := range []string{"/users/profile", "/users/settings"} {
+		if _, _, _, _, found := tree.Lookup(path); !found {
+			t.Fatalf("%q: expected a match after split insertion", path)
+		}
+	}
+
+	if _, _, _, _, found := tree.Lookup("/users/other"); found {
+		t.Fatalf("/users/other: expected no match")
+	}
+}
+
+func TestTreeCatchAllMatchesRemainder(t *testing.T) {
+	tree := NewTree()
+
+	if err := tree.Add("/files/*path", noop); err != nil {
+		t.Fatalf("Add: unexpected error: %v", err)
+	}
+
+	handle, _, path, params, found := tree.Lookup("/files/a/b/c.txt")
+	if !found || handle == nil {
+		t.Fatalf("expected /files/a/b/c.txt to match the catch-all route")
+	}
+
+	if path != "/files/*path" {
+		t.Fatalf("path = %q, want %q", path, "/files/*path")
+	}
+
+	if got := params["path"]; got != "a/b/c.txt" {
+		t.Fatalf("params[%q] = %q, want %q", "path", got, "a/b/c.txt")
+	}
+
+	tree.PutParams(params)
+}
+
+func TestTreeExplicitPatternFallback(t *testing.T) {
+	tree := NewTree()
+
+	if err := tree.Add("/search/{term:[a-z]+}", noop); err != nil {
+		t.Fatalf("Add: unexpected error: %v", err)
+	}
+
+	if _, _, _, params, found := tree.Lookup("/search/golang"); !found {
+		t.Fatalf("/search/golang: expected a match")
+	} else {
+		tree.PutParams(params)
+	}
+
+	if _, _, _, _, found := tree.Lookup("/search/123"); found {
+		t.Fatalf("/search/123: expected no match against {term:[a-z]+}")
+	}
+}
@@ -0,0 +1,96 @@
+package gorouter
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Named registers a new request handle like Handle, additionally recording
+// its path template under name so it can be reconstructed later with URL.
+func (router *Router) Named(name, method, path string, handle http.HandlerFunc) error {
+	if err := router.Handle(method, path, handle); err != nil {
+		return err
+	}
+
+	router.names[name] = router.fullPath(path)
+	return nil
+}
+
+// URL builds the path registered under name, substituting its
+// `{param:regex}`, `:param` and `*param` placeholders with the values
+// given in kv, which must be passed as alternating key/value pairs (e.g.
+// `router.URL("user", "id", "42")`). Each value is validated against the
+// placeholder's pattern, the same one used to match incoming requests, and
+// is path-escaped before being substituted; a catch-all value is not
+// escaped, since it may legitimately contain slashes.
+func (router *Router) URL(name string, kv ...string) (string, error) {
+	if len(kv)%2 != 0 {
+		return "", fmt.Errorf("gorouter: URL(%q): odd number of key/value arguments", name)
+	}
+
+	template, ok := router.names[name]
+	if !ok {
+		return "", fmt.Errorf("gorouter: URL(%q): no route registered under that name", name)
+	}
+
+	values := make(map[string]string, len(kv)/2)
+	for i := 0; i < len(kv); i += 2 {
+		values[kv[i]] = kv[i+1]
+	}
+
+	var b strings.Builder
+	remaining := template
+
+	for remaining != "" {
+		token, start, kind := findWildcard(remaining)
+		if start < 0 {
+			b.WriteString(remaining)
+			break
+		}
+
+		b.WriteString(remaining[:start])
+
+		if kind == catchAllNode {
+			paramName := token[1:]
+
+			value, ok := values[paramName]
+			if !ok {
+				return "", fmt.Errorf("gorouter: URL(%q): missing value for %q", name, paramName)
+			}
+
+			b.WriteString(value)
+			break
+		}
+
+		paramName, pattern := parseParamToken(token)
+
+		value, ok := values[paramName]
+		if !ok {
+			return "", fmt.Errorf("gorouter: URL(%q): missing value for %q", name, paramName)
+		}
+
+		if pattern != nil && !pattern.MatchString(value) {
+			return "", fmt.Errorf("gorouter: URL(%q): value %q for %q does not match its pattern", name, value, paramName)
+		}
+
+		b.WriteString(url.PathEscape(value))
+		remaining = remaining[start+len(token):]
+	}
+
+	return b.String(), nil
+}
+
+// Redirect builds the URL for name via URL and writes an
+// http.StatusFound redirect to it, a convenience for handlers that just
+// completed a request tied to a named route (e.g. a form submission).
+func (router *Router) Redirect(w http.ResponseWriter, r *http.Request, name string, kv ...string) error {
+	target, err := router.URL(name, kv...)
+	if err != nil {
+		return err
+	}
+
+	http.Redirect(w, r, target, http.StatusFound)
+	return nil
+}
@@ -0,0 +1,67 @@
+package gorouter
+
+import (
+	"net/http"
+	"testing"
+)
+
+func noopHandler(w http.ResponseWriter, r *http.Request) {}
+
+func TestRouterURL(t *testing.T) {
+	router := New()
+
+	if err := router.Named("user", http.MethodGet, "/users/:id", noopHandler); err != nil {
+		t.Fatalf("Named() error = %v", err)
+	}
+
+	if err := router.Named("search", http.MethodGet, "/search/{term:[a-z]+}", noopHandler); err != nil {
+		t.Fatalf("Named() error = %v", err)
+	}
+
+	if err := router.Named("files", http.MethodGet, "/files/*path", noopHandler); err != nil {
+		t.Fatalf("Named() error = %v", err)
+	}
+
+	if err := router.Named("profile", http.MethodGet, "/profile/{slug:.+}", noopHandler); err != nil {
+		t.Fatalf("Named() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		route   string
+		kv      []string
+		want    string
+		wantErr bool
+	}{
+		{name: "simple param", route: "user", kv: []string{"id", "42"}, want: "/users/42"},
+		{name: "param is escaped", route: "profile", kv: []string{"slug", "4 2"}, want: "/profile/4%202"},
+		{name: "param fails its pattern", route: "user", kv: []string{"id", "not-a-number"}, wantErr: true},
+		{name: "constrained param", route: "search", kv: []string{"term", "gorouter"}, want: "/search/gorouter"},
+		{name: "constrained param fails its pattern", route: "search", kv: []string{"term", "G0R0UTER"}, wantErr: true},
+		{name: "catch-all is not escaped", route: "files", kv: []string{"path", "a/b c"}, want: "/files/a/b c"},
+		{name: "missing value", route: "user", kv: nil, wantErr: true},
+		{name: "odd key/value arguments", route: "user", kv: []string{"id"}, wantErr: true},
+		{name: "unknown route name", route: "missing", kv: nil, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := router.URL(tt.route, tt.kv...)
+
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("URL() error = nil, want error")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("URL() error = %v", err)
+			}
+
+			if got != tt.want {
+				t.Fatalf("URL() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}